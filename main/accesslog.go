@@ -0,0 +1,164 @@
+package main3
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// defaultAccessLogFormat is modeled on Apache's mod_log_config "combined"
+// layout; it omits the identity, user, protocol, referer and user-agent
+// fields, and adds %D and %q for latency and per-request SQL statement count
+// since mod_log_config has no query-counting directive of its own.
+//
+// Recognized tokens: %h (remote addr), %t (timestamp), %m (method),
+// %U (path), %s (status), %b (response size), %D (duration in ms),
+// %q (SQL statements executed).
+const defaultAccessLogFormat = `%h [%t] "%m %U" %s %b %Dms sql=%q`
+
+// AccessLogOptions configures the access-log middleware returned by
+// NewDbExplorerHandler.
+type AccessLogOptions struct {
+	// Output receives one formatted line per request. Defaults to os.Stdout.
+	Output io.Writer
+	// Format is the line template; see defaultAccessLogFormat for the
+	// recognized tokens. Defaults to defaultAccessLogFormat.
+	Format string
+}
+
+// NewDbExplorerHandler builds a DbExplorer and wraps it in access-log
+// middleware, so every request emits one line describing the SQL work it
+// did in addition to the usual request/response fields.
+func NewDbExplorerHandler(db *sql.DB, logOpts AccessLogOptions, opts ...Option) (http.Handler, error) {
+	e, err := NewDbExplorer(db, opts...)
+	if err != nil {
+		return nil, err
+	}
+	e.db = countingDB{delegate: e.db}
+
+	if logOpts.Output == nil {
+		logOpts.Output = os.Stdout
+	}
+	if logOpts.Format == "" {
+		logOpts.Format = defaultAccessLogFormat
+	}
+
+	return &accessLogMiddleware{next: e, opts: logOpts}, nil
+}
+
+type accessLogMiddleware struct {
+	next http.Handler
+	opts AccessLogOptions
+}
+
+func (m *accessLogMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	ctx, counter := withSQLCounter(r.Context())
+	rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+	m.next.ServeHTTP(rec, r.WithContext(ctx))
+
+	line := formatAccessLogLine(m.opts.Format, r, rec, time.Since(start), atomic.LoadInt64(counter))
+	fmt.Fprintln(m.opts.Output, line)
+}
+
+func formatAccessLogLine(format string, r *http.Request, rec *responseRecorder, duration time.Duration, sqlCount int64) string {
+	replacer := map[string]string{
+		"%h": r.RemoteAddr,
+		"%t": time.Now().Format(time.RFC3339),
+		"%m": r.Method,
+		"%U": r.URL.Path,
+		"%s": strconv.Itoa(rec.status),
+		"%b": strconv.Itoa(rec.bytes),
+		"%D": strconv.FormatInt(duration.Milliseconds(), 10),
+		"%q": strconv.FormatInt(sqlCount, 10),
+	}
+
+	var out []byte
+	for i := 0; i < len(format); i++ {
+		if format[i] == '%' && i+1 < len(format) {
+			if token, ok := replacer[format[i:i+2]]; ok {
+				out = append(out, token...)
+				i++
+				continue
+			}
+		}
+		out = append(out, format[i])
+	}
+	return string(out)
+}
+
+// responseRecorder wraps an http.ResponseWriter to capture the status code
+// and byte count the handlers below it write, since http.ResponseWriter
+// exposes neither after the fact.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if !rec.wroteHeader {
+		rec.status = http.StatusOK
+		rec.wroteHeader = true
+	}
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += n
+	return n, err
+}
+
+// sqlCounterKey is the context key under which withSQLCounter stores a
+// request's SQL statement counter.
+type sqlCounterKey struct{}
+
+// withSQLCounter returns a context carrying a fresh counter, along with a
+// pointer to it so the caller can read its final value once the request
+// has been handled.
+func withSQLCounter(ctx context.Context) (context.Context, *int64) {
+	counter := new(int64)
+	return context.WithValue(ctx, sqlCounterKey{}, counter), counter
+}
+
+func incrementSQLCounter(ctx context.Context) {
+	if counter, ok := ctx.Value(sqlCounterKey{}).(*int64); ok {
+		atomic.AddInt64(counter, 1)
+	}
+}
+
+// countingDB wraps a dbConn, incrementing the request's SQL statement
+// counter (see withSQLCounter) on every query or exec so the access log
+// can report how much database work each request did.
+type countingDB struct {
+	delegate dbConn
+}
+
+func (c countingDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	incrementSQLCounter(ctx)
+	return c.delegate.QueryContext(ctx, query, args...)
+}
+
+func (c countingDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	incrementSQLCounter(ctx)
+	return c.delegate.QueryRowContext(ctx, query, args...)
+}
+
+func (c countingDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	incrementSQLCounter(ctx)
+	return c.delegate.ExecContext(ctx, query, args...)
+}
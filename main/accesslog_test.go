@@ -0,0 +1,67 @@
+package main3
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResponseRecorderCapturesStatusAndBytes(t *testing.T) {
+	rec := &responseRecorder{ResponseWriter: httptest.NewRecorder(), status: 200}
+
+	rec.WriteHeader(404)
+	n, err := rec.Write([]byte("not found"))
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if rec.status != 404 {
+		t.Fatalf("status = %d, want 404", rec.status)
+	}
+	if rec.bytes != n || rec.bytes != len("not found") {
+		t.Fatalf("bytes = %d, want %d", rec.bytes, len("not found"))
+	}
+}
+
+func TestResponseRecorderDefaultsStatusOnImplicitWrite(t *testing.T) {
+	rec := &responseRecorder{ResponseWriter: httptest.NewRecorder(), status: 200}
+
+	if _, err := rec.Write([]byte("ok")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if rec.status != 200 {
+		t.Fatalf("status = %d, want 200", rec.status)
+	}
+}
+
+func TestFormatAccessLogLine(t *testing.T) {
+	req := httptest.NewRequest("GET", "/users", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	rec := &responseRecorder{ResponseWriter: httptest.NewRecorder(), status: 200, bytes: 42}
+
+	got := formatAccessLogLine(defaultAccessLogFormat, req, rec, 7*time.Millisecond, 3)
+	// prefix check only, since the embedded timestamp ticks across test runs
+	wantPrefix := "10.0.0.1:1234 [" + time.Now().Format("2006-01-02T15:04")
+
+	if !strings.HasPrefix(got, wantPrefix) {
+		t.Fatalf("formatAccessLogLine() = %q, want prefix %q", got, wantPrefix)
+	}
+	for _, want := range []string{`"GET /users"`, "200", "42", "7ms", "sql=3"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("formatAccessLogLine() = %q, missing %q", got, want)
+		}
+	}
+}
+
+func TestSQLCounterTracksIncrements(t *testing.T) {
+	ctx, counter := withSQLCounter(context.Background())
+	incrementSQLCounter(ctx)
+	incrementSQLCounter(ctx)
+
+	if *counter != 2 {
+		t.Fatalf("counter = %d, want 2", *counter)
+	}
+}
@@ -1,20 +1,120 @@
 package main3
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
+// ColumnMeta describes a single column as reported by the dialect's
+// DescribeTable, cached once per table so request handlers never need to probe the
+// database to learn types.
+type ColumnMeta struct {
+	Name          string
+	Type          string
+	Nullable      bool
+	PrimaryKey    bool
+	HasDefault    bool
+	AutoIncrement bool
+}
+
+// dbConn is the subset of *sql.DB that request handlers and Dialect.Insert
+// rely on. It exists so a counting wrapper (see accesslog.go) can stand in
+// for the real *sql.DB without handlers knowing the difference.
+type dbConn interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
 type DbExplorer struct {
-	db *sql.DB
+	db      dbConn
+	dialect Dialect
+	tables  []string
+	columns map[string][]ColumnMeta
+	pk      map[string]string
+}
+
+// Option customizes NewDbExplorer, e.g. to force a specific Dialect instead
+// of relying on driver auto-detection.
+type Option func(*DbExplorer)
+
+// WithDialect overrides the auto-detected Dialect. Use it when the driver
+// can't be recognized from its type name, or to run against a backend
+// other than the one auto-detection would pick.
+func WithDialect(dialect Dialect) Option {
+	return func(e *DbExplorer) { e.dialect = dialect }
+}
+
+func NewDbExplorer(db *sql.DB, opts ...Option) (*DbExplorer, error) {
+	e := &DbExplorer{
+		db:      db,
+		columns: make(map[string][]ColumnMeta),
+		pk:      make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.dialect == nil {
+		e.dialect = detectDialect(db)
+	}
+
+	ctx := context.Background()
+
+	tables, err := e.dialect.ListTables(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	e.tables = tables
+
+	for _, table := range tables {
+		cols, err := e.dialect.DescribeTable(ctx, db, table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe table %s: %w", table, err)
+		}
+		e.columns[table] = cols
+		for _, col := range cols {
+			if col.PrimaryKey {
+				e.pk[table] = col.Name
+				break
+			}
+		}
+	}
+
+	return e, nil
 }
 
-func NewDbExplorer(db *sql.DB) (*DbExplorer, error) {
-	return &DbExplorer{db: db}, nil
+// pkColumn returns the cached primary key column name for table, falling
+// back to "id" for the (unexpected) case of a table without one.
+func (e *DbExplorer) pkColumn(table string) string {
+	if pk, ok := e.pk[table]; ok {
+		return pk
+	}
+	return "id"
+}
+
+// column looks up the cached metadata for column in table, the whitelist
+// every user-supplied identifier must pass before it is quoted and
+// interpolated into a query.
+func (e *DbExplorer) column(table, column string) (ColumnMeta, bool) {
+	for _, col := range e.columns[table] {
+		if col.Name == column {
+			return col, true
+		}
+	}
+	return ColumnMeta{}, false
+}
+
+func (e *DbExplorer) hasColumn(table, column string) bool {
+	_, ok := e.column(table, column)
+	return ok
 }
 
 func (e *DbExplorer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -83,36 +183,184 @@ func scanRowToMap(rows *sql.Rows) (map[string]interface{}, error) {
 	return record, nil
 }
 
-func (e *DbExplorer) checkColumnType(columnTypes []*sql.ColumnType, columnName string, value interface{}) bool {
-	for _, colType := range columnTypes {
-		if colType.Name() == columnName {
-			// Get database type name
-			dbTypeName := strings.ToUpper(colType.DatabaseTypeName())
-
-			// Check value type
-			switch dbTypeName {
-			case "INT", "INTEGER", "BIGINT", "TINYINT", "MEDIUMINT", "SMALLINT":
-				_, ok := value.(float64)
-				return ok
-			case "FLOAT", "DOUBLE", "DECIMAL":
-				_, ok := value.(float64)
-				return ok
-			default:
-				_, ok := value.(string)
-				return ok
-			}
+// integerRange returns the inclusive value range MySQL accepts for an
+// integer column of baseType (the information_schema.columns DATA_TYPE,
+// upper-cased, with any display width stripped), honoring the unsigned
+// attribute carried in the full COLUMN_TYPE string.
+func integerRange(baseType string, unsigned bool) (min, max int64) {
+	switch baseType {
+	case "TINYINT":
+		if unsigned {
+			return 0, 255
+		}
+		return -128, 127
+	case "SMALLINT":
+		if unsigned {
+			return 0, 65535
+		}
+		return -32768, 32767
+	case "MEDIUMINT":
+		if unsigned {
+			return 0, 16777215
+		}
+		return -8388608, 8388607
+	case "INT", "INTEGER":
+		if unsigned {
+			return 0, 4294967295
+		}
+		return math.MinInt32, math.MaxInt32
+	default: // BIGINT
+		if unsigned {
+			return 0, math.MaxInt64
 		}
+		return math.MinInt64, math.MaxInt64
 	}
+}
+
+// columnBaseType strips the display width/length and any trailing
+// attributes (e.g. "unsigned") from a COLUMN_TYPE value, e.g.
+// "int(11) unsigned" -> "INT", "varchar(255)" -> "VARCHAR".
+func columnBaseType(colType string) string {
+	base := strings.ToUpper(colType)
+	if idx := strings.IndexByte(base, '('); idx != -1 {
+		base = base[:idx]
+	}
+	return strings.TrimSpace(strings.SplitN(base, " ", 2)[0])
+}
+
+func isNumericType(colType string) bool {
+	switch columnBaseType(colType) {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT", "FLOAT", "DOUBLE", "DECIMAL":
+		return true
+	}
+	return false
+}
+
+func isTemporalType(colType string) bool {
+	switch columnBaseType(colType) {
+	case "DATE", "DATETIME", "TIMESTAMP", "TIME", "YEAR":
+		return true
+	}
+	return false
+}
 
-	// Column not found
+func isTextType(colType string) bool {
+	switch columnBaseType(colType) {
+	case "VARCHAR", "CHAR", "TEXT", "TINYTEXT", "MEDIUMTEXT", "LONGTEXT":
+		return true
+	}
 	return false
 }
 
+// varcharMaxLength returns the declared length of a VARCHAR(n)/CHAR(n)
+// column, e.g. "varchar(255)" -> 255, or false for types without one.
+func varcharMaxLength(colType string) (int, bool) {
+	upper := strings.ToUpper(colType)
+	if !strings.HasPrefix(upper, "VARCHAR") && !strings.HasPrefix(upper, "CHAR") {
+		return 0, false
+	}
+	start := strings.IndexByte(colType, '(')
+	end := strings.IndexByte(colType, ')')
+	if start == -1 || end == -1 || end <= start+1 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(colType[start+1 : end])
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// checkColumnType validates value against the declared type of col,
+// enforcing NULL-ability, VARCHAR/CHAR length limits and integer width and
+// range, and returns value coerced to the concrete Go type the dialect
+// should bind it as. It is used by both createRecord and updateRecord.
+//
+// Callers must decode request bodies with json.Decoder.UseNumber so integer
+// values arrive as json.Number rather than float64: float64 can't represent
+// every int64 exactly, which silently corrupts large ids/counters before
+// validation even runs, and int64(num) on an out-of-range float64 wraps
+// instead of overflowing, which let this function pass garbage-huge values
+// straight through the range check it exists to enforce.
+func (e *DbExplorer) checkColumnType(col ColumnMeta, value interface{}) (interface{}, error) {
+	if value == nil {
+		if !col.Nullable {
+			return nil, fmt.Errorf("field %s cannot be null", col.Name)
+		}
+		return nil, nil
+	}
+
+	baseType := columnBaseType(col.Type)
+
+	switch baseType {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT":
+		num, ok := value.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("field %s have invalid type", col.Name)
+		}
+		n, err := num.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("field %s have invalid type", col.Name)
+		}
+		min, max := integerRange(baseType, strings.Contains(strings.ToUpper(col.Type), "UNSIGNED"))
+		if n < min || n > max {
+			return nil, fmt.Errorf("field %s out of range", col.Name)
+		}
+		return n, nil
+	case "FLOAT", "DOUBLE", "DECIMAL":
+		num, ok := value.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("field %s have invalid type", col.Name)
+		}
+		f, err := num.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("field %s have invalid type", col.Name)
+		}
+		return f, nil
+	default:
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("field %s have invalid type", col.Name)
+		}
+		if n, ok := varcharMaxLength(col.Type); ok && utf8.RuneCountInString(str) > n {
+			return nil, fmt.Errorf("field %s is longer than %d characters", col.Name, n)
+		}
+		return str, nil
+	}
+}
+
 func (e *DbExplorer) getTables(w http.ResponseWriter, r *http.Request) {
-	tables := []string{"items", "users"}
 	responseData := map[string]interface{}{
 		"response": map[string]interface{}{
-			"tables": tables,
+			"tables": e.tables,
+		},
+	}
+	response, err := json.Marshal(responseData)
+	if err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(response)
+}
+
+// getTableSchema returns the cached column metadata for table: name, SQL
+// type, nullability and primary key flag, sourced from the dialect's
+// DescribeTable at construction time.
+func (e *DbExplorer) getTableSchema(w http.ResponseWriter, r *http.Request, table string) {
+	columns := make([]map[string]interface{}, 0, len(e.columns[table]))
+	for _, col := range e.columns[table] {
+		columns = append(columns, map[string]interface{}{
+			"name":    col.Name,
+			"type":    col.Type,
+			"null":    col.Nullable,
+			"primary": col.PrimaryKey,
+		})
+	}
+
+	responseData := map[string]interface{}{
+		"response": map[string]interface{}{
+			"columns": columns,
 		},
 	}
 	response, err := json.Marshal(responseData)
@@ -136,7 +384,35 @@ func (e *DbExplorer) getTableRecords(w http.ResponseWriter, r *http.Request, tab
 		offset = o
 	}
 
-	rows, err := e.db.Query(fmt.Sprintf("SELECT * FROM %s LIMIT %d OFFSET %d", table, limit, offset))
+	seq := &placeholderSeq{dialect: e.dialect}
+	where, args, err := e.buildFilters(table, queryParams, seq)
+	if err != nil {
+		sendJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	orderClause, err := e.buildOrder(table, queryParams)
+	if err != nil {
+		sendJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	whereClause := ""
+	if where != "" {
+		whereClause = " WHERE " + where
+	}
+
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s%s", e.dialect.QuoteIdent(table), whereClause)
+	if err := e.db.QueryRowContext(r.Context(), countQuery, args...).Scan(&total); err != nil {
+		http.Error(w, "Failed to count records", http.StatusInternalServerError)
+		return
+	}
+
+	limitPlaceholder := seq.next()
+	offsetPlaceholder := seq.next()
+	query := fmt.Sprintf("SELECT * FROM %s%s%s LIMIT %s OFFSET %s", e.dialect.QuoteIdent(table), whereClause, orderClause, limitPlaceholder, offsetPlaceholder)
+	rows, err := e.db.QueryContext(r.Context(), query, append(append([]interface{}{}, args...), limit, offset)...)
 	if err != nil {
 		http.Error(w, "Failed to query records", http.StatusInternalServerError)
 		return
@@ -156,6 +432,7 @@ func (e *DbExplorer) getTableRecords(w http.ResponseWriter, r *http.Request, tab
 	responseData := map[string]interface{}{
 		"response": map[string]interface{}{
 			"records": records,
+			"total":   total,
 		},
 	}
 
@@ -168,9 +445,140 @@ func (e *DbExplorer) getTableRecords(w http.ResponseWriter, r *http.Request, tab
 	w.Write(response)
 }
 
+// reservedQueryParams are consumed by pagination, ordering and the
+// full-text search, so they are never treated as column filters.
+var reservedQueryParams = map[string]bool{
+	"limit": true, "offset": true, "q": true, "order_by": true, "order": true,
+}
+
+// buildFilters translates query parameters into a parameterized SQL WHERE
+// clause against the cached schema of table: "col" for equality,
+// "col_min"/"col_max" for a numeric range, "col_after"/"col_before" for a
+// temporal range, and "q" for a LIKE search across all VARCHAR/TEXT
+// columns. Unknown columns or range filters on non-rangeable columns
+// return an error describing the offending parameter.
+func (e *DbExplorer) buildFilters(table string, params url.Values, seq *placeholderSeq) (string, []interface{}, error) {
+	var clauses []string
+	var args []interface{}
+
+	for key, values := range params {
+		if reservedQueryParams[key] || len(values) == 0 {
+			continue
+		}
+		value := values[0]
+
+		if base, ok := strings.CutSuffix(key, "_min"); ok {
+			clause, arg, err := e.rangeFilter(table, base, key, value, isNumericType, ">=", seq)
+			if err != nil {
+				return "", nil, err
+			}
+			clauses = append(clauses, clause)
+			args = append(args, arg)
+			continue
+		}
+		if base, ok := strings.CutSuffix(key, "_max"); ok {
+			clause, arg, err := e.rangeFilter(table, base, key, value, isNumericType, "<=", seq)
+			if err != nil {
+				return "", nil, err
+			}
+			clauses = append(clauses, clause)
+			args = append(args, arg)
+			continue
+		}
+		if base, ok := strings.CutSuffix(key, "_after"); ok {
+			clause, arg, err := e.rangeFilter(table, base, key, value, isTemporalType, ">=", seq)
+			if err != nil {
+				return "", nil, err
+			}
+			clauses = append(clauses, clause)
+			args = append(args, arg)
+			continue
+		}
+		if base, ok := strings.CutSuffix(key, "_before"); ok {
+			clause, arg, err := e.rangeFilter(table, base, key, value, isTemporalType, "<=", seq)
+			if err != nil {
+				return "", nil, err
+			}
+			clauses = append(clauses, clause)
+			args = append(args, arg)
+			continue
+		}
+
+		col, ok := e.column(table, key)
+		if !ok {
+			return "", nil, fmt.Errorf("unknown filter %s", key)
+		}
+		clauses = append(clauses, fmt.Sprintf("%s = %s", e.dialect.QuoteIdent(col.Name), seq.next()))
+		args = append(args, value)
+	}
+
+	if q := params.Get("q"); q != "" {
+		var textClauses []string
+		for _, col := range e.columns[table] {
+			if !isTextType(col.Type) {
+				continue
+			}
+			textClauses = append(textClauses, fmt.Sprintf("%s LIKE %s", e.dialect.QuoteIdent(col.Name), seq.next()))
+			args = append(args, "%"+q+"%")
+		}
+		if len(textClauses) > 0 {
+			clauses = append(clauses, "("+strings.Join(textClauses, " OR ")+")")
+		}
+	}
+
+	return strings.Join(clauses, " AND "), args, nil
+}
+
+// rangeFilter builds a single "col op $n" clause for a col_min/col_max/
+// col_after/col_before query parameter, rejecting unknown columns and
+// columns whose type doesn't satisfy accepts (e.g. a _min on a string
+// column).
+func (e *DbExplorer) rangeFilter(table, column, param, value string, accepts func(string) bool, op string, seq *placeholderSeq) (string, interface{}, error) {
+	col, ok := e.column(table, column)
+	if !ok || !accepts(col.Type) {
+		return "", nil, fmt.Errorf("unknown filter %s", param)
+	}
+	if isNumericType(col.Type) {
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid value for %s", param)
+		}
+		return fmt.Sprintf("%s %s %s", e.dialect.QuoteIdent(col.Name), op, seq.next()), n, nil
+	}
+	return fmt.Sprintf("%s %s %s", e.dialect.QuoteIdent(col.Name), op, seq.next()), value, nil
+}
+
+// buildOrder translates order_by/order query parameters into an " ORDER
+// BY ..." clause, validating order_by against the cached schema whitelist.
+func (e *DbExplorer) buildOrder(table string, params url.Values) (string, error) {
+	orderBy := params.Get("order_by")
+	if orderBy == "" {
+		return "", nil
+	}
+	if !e.hasColumn(table, orderBy) {
+		return "", fmt.Errorf("unknown order_by column %s", orderBy)
+	}
+
+	switch direction := strings.ToUpper(params.Get("order")); direction {
+	case "", "ASC":
+		return fmt.Sprintf(" ORDER BY %s ASC", e.dialect.QuoteIdent(orderBy)), nil
+	case "DESC":
+		return fmt.Sprintf(" ORDER BY %s DESC", e.dialect.QuoteIdent(orderBy)), nil
+	default:
+		return "", fmt.Errorf("invalid order direction %s", direction)
+	}
+}
+
 func (e *DbExplorer) getRecord(w http.ResponseWriter, r *http.Request, table string, id string) {
-	query := fmt.Sprintf("SELECT * FROM %s WHERE id = %s", table, id)
-	rows, err := e.db.Query(query)
+	idValue, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		sendJSONError(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	seq := &placeholderSeq{dialect: e.dialect}
+	query := fmt.Sprintf("SELECT * FROM %s WHERE %s = %s", e.dialect.QuoteIdent(table), e.dialect.QuoteIdent(e.pkColumn(table)), seq.next())
+	rows, err := e.db.QueryContext(r.Context(), query, idValue)
 	if err != nil {
 		http.Error(w, "Failed to get record", http.StatusInternalServerError)
 		return
@@ -206,6 +614,7 @@ func (e *DbExplorer) getRecord(w http.ResponseWriter, r *http.Request, table str
 
 func (e *DbExplorer) createRecord(w http.ResponseWriter, r *http.Request, table string) {
 	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
 	var record map[string]interface{}
 	err := decoder.Decode(&record)
 	if err != nil {
@@ -213,30 +622,51 @@ func (e *DbExplorer) createRecord(w http.ResponseWriter, r *http.Request, table
 		return
 	}
 
+	pk := e.pkColumn(table)
+	if record[pk] != nil {
+		sendJSONError(w, fmt.Sprintf("%s field cannot be updated", pk), http.StatusBadRequest)
+		return
+	}
+
 	cols := make([]string, 0)
 	vals := make([]interface{}, 0)
+	provided := make(map[string]bool, len(record))
 
 	for column, value := range record {
-		if column == "id" {
+		if column == pk {
 			continue
 		}
+		col, ok := e.column(table, column)
+		if !ok {
+			sendJSONError(w, fmt.Sprintf("field %s not found", column), http.StatusBadRequest)
+			return
+		}
+		normalized, err := e.checkColumnType(col, value)
+		if err != nil {
+			sendJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		provided[column] = true
 		cols = append(cols, column)
-		vals = append(vals, value)
+		vals = append(vals, normalized)
 	}
 
-	columns := strings.Join(cols, ", ")
-	placeholders := "?" + strings.Repeat(",?", len(cols)-1)
+	for _, col := range e.columns[table] {
+		if col.PrimaryKey || col.Nullable || col.HasDefault || col.AutoIncrement || provided[col.Name] {
+			continue
+		}
+		sendJSONError(w, fmt.Sprintf("field %s is required", col.Name), http.StatusBadRequest)
+		return
+	}
 
-	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, columns, placeholders)
-	result, err := e.db.Exec(query, vals...)
-	if err != nil {
-		sendJSONError(w, "failed to create record", http.StatusInternalServerError)
+	if len(cols) == 0 {
+		sendJSONError(w, "no fields to insert", http.StatusBadRequest)
 		return
 	}
 
-	id, err := result.LastInsertId()
+	id, err := e.dialect.Insert(r.Context(), e.db, table, pk, cols, vals)
 	if err != nil {
-		sendJSONError(w, "failed to get last inserted ID", http.StatusInternalServerError)
+		sendJSONError(w, "failed to create record", http.StatusInternalServerError)
 		return
 	}
 
@@ -252,6 +682,7 @@ func (e *DbExplorer) createRecord(w http.ResponseWriter, r *http.Request, table
 
 func (e *DbExplorer) updateRecord(w http.ResponseWriter, r *http.Request, table string, id string) {
 	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
 	var record map[string]interface{}
 	err := decoder.Decode(&record)
 	if err != nil {
@@ -260,56 +691,51 @@ func (e *DbExplorer) updateRecord(w http.ResponseWriter, r *http.Request, table
 	}
 
 	// Check if id is a number
-	_, err = strconv.Atoi(id)
+	idValue, err := strconv.ParseInt(id, 10, 64)
 	if err != nil {
 		sendJSONError(w, "invalid id", http.StatusBadRequest)
 		return
 	}
 
-	// Check if id is not being updated
-	if record["id"] != nil {
-		sendJSONError(w, "id field cannot be updated", http.StatusBadRequest)
-		return
-	}
+	pk := e.pkColumn(table)
 
-	// Get column types for the table
-	columnTypes, err := e.db.Query(fmt.Sprintf("SELECT * FROM %s LIMIT 1", table))
-	if err != nil {
-		sendJSONError(w, "failed to get column types", http.StatusInternalServerError)
-		return
-	}
-	defer columnTypes.Close()
-	columnTypesSlice, err := columnTypes.ColumnTypes()
-	if err != nil {
-		sendJSONError(w, "failed to get column types", http.StatusInternalServerError)
+	// Check if id is not being updated
+	if record[pk] != nil {
+		sendJSONError(w, fmt.Sprintf("%s field cannot be updated", pk), http.StatusBadRequest)
 		return
 	}
 
 	// Prepare SET clause
+	seq := &placeholderSeq{dialect: e.dialect}
 	var setClause []string
+	var values []interface{}
 	for fieldName, fieldValue := range record {
-		if fieldName == "id" {
+		if fieldName == pk {
 			continue
 		}
 
-		if !e.checkColumnType(columnTypesSlice, fieldName, fieldValue) {
-			sendJSONError(w, fmt.Sprintf("field %s have invalid type", fieldName), http.StatusBadRequest)
+		col, ok := e.column(table, fieldName)
+		if !ok {
+			sendJSONError(w, fmt.Sprintf("field %s not found", fieldName), http.StatusBadRequest)
 			return
 		}
 
-		setClause = append(setClause, fmt.Sprintf("%s = ?", fieldName))
+		normalized, err := e.checkColumnType(col, fieldValue)
+		if err != nil {
+			sendJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		setClause = append(setClause, fmt.Sprintf("%s = %s", e.dialect.QuoteIdent(fieldName), seq.next()))
+		values = append(values, normalized)
 	}
 
 	// Prepare UPDATE query
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = ?", table, strings.Join(setClause, ", "))
-	values := make([]interface{}, 0, len(record))
-	for _, v := range record {
-		values = append(values, v)
-	}
-	values = append(values, id)
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = %s", e.dialect.QuoteIdent(table), strings.Join(setClause, ", "), e.dialect.QuoteIdent(pk), seq.next())
+	values = append(values, idValue)
 
 	// Execute query
-	result, err := e.db.Exec(query, values...)
+	result, err := e.db.ExecContext(r.Context(), query, values...)
 	if err != nil {
 		sendJSONError(w, "failed to update record", http.StatusInternalServerError)
 		return
@@ -337,10 +763,15 @@ func (e *DbExplorer) updateRecord(w http.ResponseWriter, r *http.Request, table
 }
 
 func (e *DbExplorer) deleteRecord(w http.ResponseWriter, r *http.Request, table string, id string) {
-	defer e.db.Close()
+	idValue, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		sendJSONError(w, "invalid id", http.StatusBadRequest)
+		return
+	}
 
-	query := fmt.Sprintf("DELETE FROM %s WHERE id = %s", table, id)
-	result, err := e.db.Exec(query)
+	seq := &placeholderSeq{dialect: e.dialect}
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s = %s", e.dialect.QuoteIdent(table), e.dialect.QuoteIdent(e.pkColumn(table)), seq.next())
+	result, err := e.db.ExecContext(r.Context(), query, idValue)
 	if err != nil {
 		http.Error(w, "Failed to delete record", http.StatusInternalServerError)
 		return
@@ -353,13 +784,11 @@ func (e *DbExplorer) deleteRecord(w http.ResponseWriter, r *http.Request, table
 	w.Write(responseJSON)
 }
 
-func (e *DbExplorer) tableExists(tableName string) (bool, error) {
-	var exists bool
-	err := e.db.QueryRow("SELECT EXISTS (SELECT 1 FROM information_schema.tables WHERE table_name = ?)", tableName).Scan(&exists)
-	if err != nil {
-		return false, err
-	}
-	return exists, nil
+// tableExists reports whether tableName is one of the tables discovered at
+// construction time, so checking it no longer costs a round trip.
+func (e *DbExplorer) tableExists(tableName string) bool {
+	_, ok := e.columns[tableName]
+	return ok
 }
 
 func (e *DbExplorer) handleRequest(w http.ResponseWriter, r *http.Request) {
@@ -374,16 +803,9 @@ func (e *DbExplorer) handleRequest(w http.ResponseWriter, r *http.Request) {
 		id = pathParts[1]
 	}
 
-	if table != "" {
-		exists, err := e.tableExists(table)
-		if err != nil {
-			sendJSONError(w, "Failed to check table existence", http.StatusInternalServerError)
-			return
-		}
-		if !exists {
-			sendJSONError(w, "unknown table", http.StatusNotFound)
-			return
-		}
+	if table != "" && !e.tableExists(table) {
+		sendJSONError(w, "unknown table", http.StatusNotFound)
+		return
 	}
 
 	switch r.Method {
@@ -392,6 +814,8 @@ func (e *DbExplorer) handleRequest(w http.ResponseWriter, r *http.Request) {
 			e.getTables(w, r)
 		} else if id == "" {
 			e.getTableRecords(w, r, table)
+		} else if id == "schema" || r.URL.Query().Get("schema") == "1" {
+			e.getTableSchema(w, r, table)
 		} else {
 			e.getRecord(w, r, table, id)
 		}
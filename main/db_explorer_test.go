@@ -0,0 +1,265 @@
+package main3
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// newTestExplorer builds a DbExplorer with a fixed schema cache and no
+// underlying *sql.DB, so that any code path which forgot to validate an
+// identifier or value before touching the database panics on the nil
+// pointer instead of silently executing injected SQL.
+func newTestExplorer() *DbExplorer {
+	columns := []ColumnMeta{
+		{Name: "id", Type: "int(11)", Nullable: false, PrimaryKey: true},
+		{Name: "name", Type: "varchar(255)", Nullable: false},
+	}
+	return &DbExplorer{
+		dialect: mysqlDialect{},
+		tables:  []string{"users"},
+		columns: map[string][]ColumnMeta{"users": columns},
+		pk:      map[string]string{"users": "id"},
+	}
+}
+
+func TestHandleRequestRejectsInjectionPayloads(t *testing.T) {
+	cases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "non-numeric id with injected clause",
+			method:     "GET",
+			path:       "/users/1%20OR%201=1",
+			wantStatus: 400,
+		},
+		{
+			name:       "table name with trailing statement",
+			method:     "GET",
+			path:       "/users%3B%20DROP%20TABLE%20users",
+			wantStatus: 404,
+		},
+		{
+			name:       "create with unknown column containing backtick",
+			method:     "PUT",
+			path:       "/users",
+			body:       `{"name` + "`" + `": "x"}`,
+			wantStatus: 400,
+		},
+		{
+			name:       "update with unknown injected column name",
+			method:     "POST",
+			path:       "/users/1",
+			body:       `{"id OR 1=1": "x"}`,
+			wantStatus: 400,
+		},
+		{
+			name:       "delete with non-numeric id",
+			method:     "DELETE",
+			path:       "/users/1%20OR%201=1",
+			wantStatus: 400,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := newTestExplorer()
+			req := httptest.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.body))
+			rec := httptest.NewRecorder()
+
+			e.handleRequest(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("got status %d, want %d (body: %s)", rec.Code, tc.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestMySQLDialectQuoteIdentEscapesBackticks(t *testing.T) {
+	got := mysqlDialect{}.QuoteIdent("a`b")
+	want := "`a``b`"
+	if got != want {
+		t.Fatalf("QuoteIdent(%q) = %q, want %q", "a`b", got, want)
+	}
+}
+
+func TestPostgresDialectQuoteIdentEscapesDoubleQuotes(t *testing.T) {
+	got := postgresDialect{}.QuoteIdent(`a"b`)
+	want := `"a""b"`
+	if got != want {
+		t.Fatalf("QuoteIdent(%q) = %q, want %q", `a"b`, got, want)
+	}
+}
+
+func TestDialectForDriverTypeName(t *testing.T) {
+	cases := []struct {
+		name       string
+		driverName string
+		want       string
+	}{
+		{"sqlite", "*sqlite3.SQLiteDriver", "sqlite"},
+		{"mysql", "*mysql.MySQLDriver", "mysql"},
+		{"lib/pq", "*pq.Driver", "postgres"},
+		{"pgx stdlib adapter", "*stdlib.Driver", "postgres"},
+		{"unknown falls back to mysql", "*somedriver.Driver", "mysql"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := dialectForDriverTypeName(tc.driverName).Name(); got != tc.want {
+				t.Fatalf("dialectForDriverTypeName(%q) = %q, want %q", tc.driverName, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPlaceholderSeq(t *testing.T) {
+	cases := []struct {
+		name    string
+		dialect Dialect
+		want    []string
+	}{
+		{"mysql repeats ?", mysqlDialect{}, []string{"?", "?", "?"}},
+		{"sqlite repeats ?", sqliteDialect{}, []string{"?", "?", "?"}},
+		{"postgres numbers params", postgresDialect{}, []string{"$1", "$2", "$3"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			seq := &placeholderSeq{dialect: tc.dialect}
+			for i, want := range tc.want {
+				if got := seq.next(); got != want {
+					t.Fatalf("next() #%d = %q, want %q", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func newFacetedTestExplorer() *DbExplorer {
+	columns := []ColumnMeta{
+		{Name: "id", Type: "int(11)", Nullable: false, PrimaryKey: true},
+		{Name: "name", Type: "varchar(255)", Nullable: false},
+		{Name: "price", Type: "decimal(10,2)", Nullable: false},
+		{Name: "created_at", Type: "datetime", Nullable: false},
+	}
+	return &DbExplorer{
+		dialect: mysqlDialect{},
+		tables:  []string{"items"},
+		columns: map[string][]ColumnMeta{"items": columns},
+		pk:      map[string]string{"items": "id"},
+	}
+}
+
+func TestBuildFilters(t *testing.T) {
+	e := newFacetedTestExplorer()
+
+	cases := []struct {
+		name      string
+		query     string
+		wantErr   bool
+		wantArgs  int
+		wantWhere bool
+	}{
+		{"equality on known column", "name=foo", false, 1, true},
+		{"numeric min range", "price_min=10", false, 1, true},
+		{"numeric max range", "price_max=50", false, 1, true},
+		{"temporal after range", "created_at_after=2024-01-01", false, 1, true},
+		{"full text search across text columns", "q=hello", false, 1, true},
+		{"unknown column filter", "bogus=1", true, 0, false},
+		{"range suffix on string column", "name_min=a", true, 0, false},
+		{"non-numeric value for numeric range", "price_min=abc", true, 0, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			params, err := url.ParseQuery(tc.query)
+			if err != nil {
+				t.Fatalf("invalid query: %v", err)
+			}
+			seq := &placeholderSeq{dialect: e.dialect}
+			where, args, err := e.buildFilters("items", params, seq)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("buildFilters(%q) error = %v, wantErr %v", tc.query, err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if (where != "") != tc.wantWhere {
+				t.Fatalf("buildFilters(%q) where = %q, wantWhere %v", tc.query, where, tc.wantWhere)
+			}
+			if len(args) != tc.wantArgs {
+				t.Fatalf("buildFilters(%q) args = %v, want %d args", tc.query, args, tc.wantArgs)
+			}
+		})
+	}
+}
+
+func TestBuildOrder(t *testing.T) {
+	e := newFacetedTestExplorer()
+
+	cases := []struct {
+		name    string
+		query   string
+		wantErr bool
+	}{
+		{"no order_by", "", false},
+		{"known column ascending", "order_by=price", false},
+		{"known column descending", "order_by=price&order=desc", false},
+		{"unknown order_by column", "order_by=bogus", true},
+		{"invalid order direction", "order_by=price&order=sideways", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			params, err := url.ParseQuery(tc.query)
+			if err != nil {
+				t.Fatalf("invalid query: %v", err)
+			}
+			_, err = e.buildOrder("items", params)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("buildOrder(%q) error = %v, wantErr %v", tc.query, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckColumnType(t *testing.T) {
+	e := &DbExplorer{}
+
+	cases := []struct {
+		name    string
+		col     ColumnMeta
+		value   interface{}
+		wantErr bool
+	}{
+		{"not null rejects null", ColumnMeta{Name: "name", Type: "varchar(5)", Nullable: false}, nil, true},
+		{"nullable accepts null", ColumnMeta{Name: "name", Type: "varchar(5)", Nullable: true}, nil, false},
+		{"varchar within length", ColumnMeta{Name: "name", Type: "varchar(5)"}, "abcde", false},
+		{"varchar over length", ColumnMeta{Name: "name", Type: "varchar(5)"}, "abcdef", true},
+		{"varchar counts runes not bytes for multi-byte characters", ColumnMeta{Name: "name", Type: "varchar(5)"}, "café!", false},
+		{"tinyint in range", ColumnMeta{Name: "age", Type: "tinyint(4)"}, json.Number("127"), false},
+		{"tinyint out of range", ColumnMeta{Name: "age", Type: "tinyint(4)"}, json.Number("128"), true},
+		{"tinyint unsigned in range", ColumnMeta{Name: "age", Type: "tinyint(3) unsigned"}, json.Number("200"), false},
+		{"int rejects fractional value", ColumnMeta{Name: "age", Type: "int(11)"}, json.Number("1.5"), true},
+		{"int rejects non-numeric value", ColumnMeta{Name: "age", Type: "int(11)"}, "1", true},
+		{"bigint in range", ColumnMeta{Name: "id", Type: "bigint(20)"}, json.Number("9223372036854775807"), false},
+		{"bigint rejects value past int64 range instead of wrapping", ColumnMeta{Name: "id", Type: "bigint(20)"}, json.Number("100000000000000000000"), true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := e.checkColumnType(tc.col, tc.value)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("checkColumnType(%+v, %v) error = %v, wantErr %v", tc.col, tc.value, err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package main3
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Dialect hides the SQL differences between backends behind a single
+// interface so DbExplorer's handlers never embed backend-specific SQL:
+// identifier quoting, placeholder style, schema introspection, and how an
+// inserted row's generated id is obtained.
+type Dialect interface {
+	// Name identifies the dialect, mostly for diagnostics.
+	Name() string
+	// QuoteIdent quotes a table or column name for safe interpolation.
+	// Callers must only pass identifiers already validated against the
+	// cached schema whitelist.
+	QuoteIdent(name string) string
+	// Placeholder returns the bind placeholder for the n-th parameter of a
+	// query (1-indexed), e.g. "?" for MySQL/SQLite, "$1" for PostgreSQL.
+	Placeholder(n int) string
+	// ListTables discovers the tables of the current database.
+	ListTables(ctx context.Context, db *sql.DB) ([]string, error)
+	// DescribeTable fetches the column metadata for table.
+	DescribeTable(ctx context.Context, db *sql.DB, table string) ([]ColumnMeta, error)
+	// Insert runs an INSERT of columns/values into table and returns the
+	// generated value of the pk column.
+	Insert(ctx context.Context, db dbConn, table, pk string, columns []string, values []interface{}) (int64, error)
+}
+
+// detectDialect guesses the Dialect from the registered driver's type name,
+// so NewDbExplorer works out of the box for the common drivers without
+// forcing callers to pass WithDialect explicitly.
+func detectDialect(db *sql.DB) Dialect {
+	return dialectForDriverTypeName(fmt.Sprintf("%T", db.Driver()))
+}
+
+// dialectForDriverTypeName maps a %T-formatted driver type name to a
+// Dialect. Split out from detectDialect so the mapping can be unit tested
+// against known driver type names without needing a live *sql.DB.
+//
+// Known type names: "*sqlite3.SQLiteDriver" (mattn/go-sqlite3),
+// "*mysql.MySQLDriver" (go-sql-driver/mysql), "*pq.Driver" (lib/pq), and
+// "*stdlib.Driver" (jackc/pgx/v5/stdlib, the modern pgx database/sql
+// adapter).
+func dialectForDriverTypeName(driverName string) Dialect {
+	switch {
+	case strings.Contains(driverName, "sqlite"):
+		return sqliteDialect{}
+	case strings.Contains(driverName, "pq.") || strings.Contains(driverName, "pgx") || strings.Contains(driverName, "stdlib"):
+		return postgresDialect{}
+	default:
+		return mysqlDialect{}
+	}
+}
+
+// quoteWith quotes an identifier with the given quote character,
+// doubling any occurrence of it inside the name (the standard SQL escape).
+func quoteWith(quote byte, name string) string {
+	q := string(quote)
+	return q + strings.ReplaceAll(name, q, q+q) + q
+}
+
+// placeholderSeq hands out dialect-correct bind placeholders for
+// successive parameters of a single query, so a query assembled from
+// several independently-built clauses (filters, then LIMIT/OFFSET, or SET
+// clauses, then the WHERE id) numbers them consistently for dialects like
+// PostgreSQL that need $1, $2, ... rather than repeated "?".
+type placeholderSeq struct {
+	dialect Dialect
+	n       int
+}
+
+func (s *placeholderSeq) next() string {
+	s.n++
+	return s.dialect.Placeholder(s.n)
+}
+
+// mysqlDialect is the original behavior: information_schema introspection,
+// backtick-quoted identifiers, "?" placeholders and LastInsertId.
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string { return "mysql" }
+
+func (mysqlDialect) QuoteIdent(name string) string { return quoteWith('`', name) }
+
+func (mysqlDialect) Placeholder(int) string { return "?" }
+
+func (mysqlDialect) ListTables(ctx context.Context, db *sql.DB) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE()")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+func (mysqlDialect) DescribeTable(ctx context.Context, db *sql.DB, table string) ([]ColumnMeta, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT column_name, column_type, is_nullable, column_key, column_default, extra
+		 FROM information_schema.columns
+		 WHERE table_schema = DATABASE() AND table_name = ?
+		 ORDER BY ordinal_position`,
+		table,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols := make([]ColumnMeta, 0)
+	for rows.Next() {
+		var name, colType, isNullable, columnKey, extra string
+		var columnDefault sql.NullString
+		if err := rows.Scan(&name, &colType, &isNullable, &columnKey, &columnDefault, &extra); err != nil {
+			return nil, err
+		}
+		cols = append(cols, ColumnMeta{
+			Name:          name,
+			Type:          colType,
+			Nullable:      isNullable == "YES",
+			PrimaryKey:    columnKey == "PRI",
+			HasDefault:    columnDefault.Valid,
+			AutoIncrement: strings.Contains(extra, "auto_increment"),
+		})
+	}
+	return cols, rows.Err()
+}
+
+func (d mysqlDialect) Insert(ctx context.Context, db dbConn, table, pk string, columns []string, values []interface{}) (int64, error) {
+	quoted := make([]string, len(columns))
+	placeholders := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = d.QuoteIdent(col)
+		placeholders[i] = "?"
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", d.QuoteIdent(table), strings.Join(quoted, ", "), strings.Join(placeholders, ","))
+	result, err := db.ExecContext(ctx, query, values...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// sqliteDialect introspects via sqlite_master and PRAGMA table_info. SQLite
+// accepts the same "?" placeholders as MySQL and also supports LastInsertId.
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string { return "sqlite" }
+
+func (sqliteDialect) QuoteIdent(name string) string { return quoteWith('"', name) }
+
+func (sqliteDialect) Placeholder(int) string { return "?" }
+
+func (sqliteDialect) ListTables(ctx context.Context, db *sql.DB) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+func (d sqliteDialect) DescribeTable(ctx context.Context, db *sql.DB, table string) ([]ColumnMeta, error) {
+	// PRAGMA statements don't accept bound parameters; safe here because
+	// table only ever comes from this dialect's own ListTables result.
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", d.QuoteIdent(table)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols := make([]ColumnMeta, 0)
+	for rows.Next() {
+		var cid int
+		var name, colType string
+		var notNull, pk int
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return nil, err
+		}
+		cols = append(cols, ColumnMeta{
+			Name:       name,
+			Type:       colType,
+			Nullable:   notNull == 0,
+			PrimaryKey: pk != 0,
+			HasDefault: defaultValue.Valid,
+			// SQLite's rowid alias (an INTEGER PRIMARY KEY column) behaves
+			// like an auto-incrementing id even without an explicit
+			// AUTOINCREMENT keyword, which PRAGMA table_info doesn't expose.
+			AutoIncrement: pk != 0 && strings.EqualFold(colType, "INTEGER"),
+		})
+	}
+	return cols, rows.Err()
+}
+
+func (d sqliteDialect) Insert(ctx context.Context, db dbConn, table, pk string, columns []string, values []interface{}) (int64, error) {
+	quoted := make([]string, len(columns))
+	placeholders := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = d.QuoteIdent(col)
+		placeholders[i] = "?"
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", d.QuoteIdent(table), strings.Join(quoted, ", "), strings.Join(placeholders, ","))
+	result, err := db.ExecContext(ctx, query, values...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// postgresDialect introspects via pg_catalog, quotes with double quotes,
+// uses $n placeholders and obtains the generated id via INSERT ... RETURNING
+// since PostgreSQL's driver does not implement sql.Result.LastInsertId.
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string { return "postgres" }
+
+func (postgresDialect) QuoteIdent(name string) string { return quoteWith('"', name) }
+
+func (postgresDialect) Placeholder(n int) string { return fmt.Sprintf("$%d", n) }
+
+func (postgresDialect) ListTables(ctx context.Context, db *sql.DB) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT tablename FROM pg_catalog.pg_tables WHERE schemaname = 'public'")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+func (postgresDialect) DescribeTable(ctx context.Context, db *sql.DB, table string) ([]ColumnMeta, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT a.attname,
+		        format_type(a.atttypid, a.atttypmod),
+		        NOT a.attnotnull,
+		        EXISTS (
+		            SELECT 1 FROM pg_constraint c
+		            WHERE c.conrelid = a.attrelid AND a.attnum = ANY(c.conkey) AND c.contype = 'p'
+		        ),
+		        a.atthasdef
+		 FROM pg_attribute a
+		 JOIN pg_class t ON t.oid = a.attrelid
+		 WHERE t.relname = $1 AND a.attnum > 0 AND NOT a.attisdropped
+		 ORDER BY a.attnum`,
+		table,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols := make([]ColumnMeta, 0)
+	for rows.Next() {
+		var name, colType string
+		var nullable, primaryKey, hasDefault bool
+		if err := rows.Scan(&name, &colType, &nullable, &primaryKey, &hasDefault); err != nil {
+			return nil, err
+		}
+		cols = append(cols, ColumnMeta{
+			Name:          name,
+			Type:          colType,
+			Nullable:      nullable,
+			PrimaryKey:    primaryKey,
+			HasDefault:    hasDefault,
+			AutoIncrement: primaryKey && strings.Contains(colType, "serial"),
+		})
+	}
+	return cols, rows.Err()
+}
+
+func (d postgresDialect) Insert(ctx context.Context, db dbConn, table, pk string, columns []string, values []interface{}) (int64, error) {
+	quoted := make([]string, len(columns))
+	placeholders := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = d.QuoteIdent(col)
+		placeholders[i] = d.Placeholder(i + 1)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING %s",
+		d.QuoteIdent(table), strings.Join(quoted, ", "), strings.Join(placeholders, ","), d.QuoteIdent(pk))
+
+	var id int64
+	err := db.QueryRowContext(ctx, query, values...).Scan(&id)
+	return id, err
+}